@@ -0,0 +1,233 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Hubble
+
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwk is a single entry of a JSON Web Key Set, as returned by an OIDC
+// provider's jwks_uri.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	// RSA fields.
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC fields.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// publicKey decodes k into the crypto public key it represents.
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		eb, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA exponent: %w", err)
+		}
+		e := 0
+		for _, b := range eb {
+			e = e<<8 | int(b)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: e}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported EC curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC x coordinate: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC y coordinate: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// JWKSKeySource is a KeySource backed by a JSON Web Key Set fetched from a
+// URL or read from a local file, refreshed in the background on a fixed
+// interval. It implements KeySource.
+type JWKSKeySource struct {
+	url      string
+	file     string
+	client   *http.Client
+	interval time.Duration
+
+	mu   sync.RWMutex
+	keys map[string]any // kid -> public key
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewJWKSKeySource creates a JWKSKeySource fetching from url (when file is
+// empty) or reading from file, refreshing every interval. The initial fetch
+// happens synchronously so that startup fails fast on a bad JWKS.
+func NewJWKSKeySource(url, file string, interval time.Duration) (*JWKSKeySource, error) {
+	s := &JWKSKeySource{
+		url:      url,
+		file:     file,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		interval: interval,
+		stopCh:   make(chan struct{}),
+	}
+
+	if err := s.refresh(); err != nil {
+		return nil, fmt.Errorf("failed to fetch initial JWKS: %w", err)
+	}
+
+	go s.refreshLoop()
+	return s, nil
+}
+
+// Stop stops the background refresh loop.
+func (s *JWKSKeySource) Stop() {
+	s.stopOnce.Do(func() { close(s.stopCh) })
+}
+
+func (s *JWKSKeySource) refreshLoop() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			if err := s.refresh(); err != nil {
+				slog.Warn("failed to refresh Hubble JWT JWKS", "error", err, "url", s.url, "file", s.file)
+			}
+		}
+	}
+}
+
+func (s *JWKSKeySource) refresh() error {
+	body, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	var set jwkSet
+	if err := json.Unmarshal(body, &set); err != nil {
+		return fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		pub, err := k.publicKey()
+		if err != nil {
+			slog.Warn("skipping unsupported key in Hubble JWT JWKS", "kid", k.Kid, "error", err)
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("JWKS contains no usable keys")
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *JWKSKeySource) load() ([]byte, error) {
+	if s.file != "" {
+		return os.ReadFile(s.file)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build JWKS request: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS endpoint %s returned status %d", s.url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// keyfunc resolves the public key used to verify token, by its "kid"
+// header, falling back to the sole configured key if there is exactly one
+// and the token carries no "kid".
+func (s *JWKSKeySource) keyfunc(token *jwt.Token) (any, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if len(s.keys) == 1 {
+			for _, key := range s.keys {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("token has no key id and JWKS has more than one key")
+	}
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
+
+// VerifyAndParse implements KeySource: it verifies token's signature
+// against the current JWKS and returns its claims. Standard "exp"/"nbf"
+// claims are enforced by the underlying JWT library.
+func (s *JWKSKeySource) VerifyAndParse(token string) (map[string]any, error) {
+	parsed, err := jwt.Parse(token, s.keyfunc, jwt.WithValidMethods([]string{
+		"RS256", "RS384", "RS512",
+		"ES256", "ES384", "ES512",
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("unexpected claims type %T", parsed.Claims)
+	}
+	return claims, nil
+}
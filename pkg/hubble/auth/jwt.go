@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Hubble
+
+// Package auth implements authentication for the Hubble gRPC server, in
+// addition to the TLS/mTLS already provided by the server's listener
+// configuration.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Mode selects how JWT authentication combines with mTLS client certificate
+// verification when both are configured.
+type Mode string
+
+const (
+	// ModeAnd requires both mTLS and JWT authentication to succeed.
+	ModeAnd Mode = "and"
+	// ModeOr accepts either mTLS or a valid JWT.
+	ModeOr Mode = "or"
+)
+
+// Claims is the set of JWT claims an authenticated request must satisfy.
+type Claims struct {
+	// Issuer is the required "iss" claim value.
+	Issuer string
+	// Audiences lists the accepted "aud" claim values; a token matching any
+	// one of them is accepted.
+	Audiences []string
+	// Required maps additional claim names to their comma-separated list of
+	// allowed values.
+	Required map[string]string
+}
+
+// KeySource resolves the keys used to verify a JWT's signature. A JWKS
+// implementation refreshes its key set in the background on a fixed
+// interval derived from hubble-jwt-refresh-interval.
+type KeySource interface {
+	// VerifyAndParse verifies the token's signature against the current key
+	// set and returns its claims.
+	VerifyAndParse(token string) (map[string]any, error)
+}
+
+// Authenticator validates bearer tokens presented to the Hubble gRPC server
+// and can be combined with mTLS peer verification via Mode.
+type Authenticator struct {
+	keys   KeySource
+	claims Claims
+	mode   Mode
+}
+
+// NewAuthenticator creates an Authenticator that verifies bearer tokens
+// against keys and the expected claims.
+func NewAuthenticator(keys KeySource, claims Claims, mode Mode) *Authenticator {
+	return &Authenticator{
+		keys:   keys,
+		claims: claims,
+		mode:   mode,
+	}
+}
+
+// UnaryInterceptor validates the bearer token on unary RPCs.
+func (a *Authenticator) UnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := a.authenticate(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// StreamInterceptor validates the bearer token on streaming RPCs.
+func (a *Authenticator) StreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.authenticate(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// authenticate extracts and verifies the bearer token from the incoming
+// context. When mode is ModeOr and the connection already presented a
+// verified client certificate, JWT verification is skipped.
+func (a *Authenticator) authenticate(ctx context.Context) error {
+	if a.mode == ModeOr && peerHasVerifiedCertificate(ctx) {
+		return nil
+	}
+
+	token, err := bearerToken(ctx)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	claims, err := a.keys.VerifyAndParse(token)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "invalid bearer token: %v", err)
+	}
+
+	if err := a.checkClaims(claims); err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if a.mode == ModeAnd && !peerHasVerifiedCertificate(ctx) {
+		return status.Error(codes.Unauthenticated, "hubble-auth-mode=and requires a verified client certificate in addition to a bearer token")
+	}
+
+	return nil
+}
+
+func (a *Authenticator) checkClaims(claims map[string]any) error {
+	if a.claims.Issuer != "" {
+		iss, _ := claims["iss"].(string)
+		if iss != a.claims.Issuer {
+			return fmt.Errorf("unexpected issuer %q", iss)
+		}
+	}
+
+	if len(a.claims.Audiences) > 0 {
+		if !audienceMatches(claims["aud"], a.claims.Audiences) {
+			return fmt.Errorf("token audience does not match any of %v", a.claims.Audiences)
+		}
+	}
+
+	for claim, allowed := range a.claims.Required {
+		value, _ := claims[claim].(string)
+		if !containsValue(strings.Split(allowed, ","), value) {
+			return fmt.Errorf("claim %q value %q not in allowed set %q", claim, value, allowed)
+		}
+	}
+
+	return nil
+}
+
+func bearerToken(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", fmt.Errorf("missing authorization metadata")
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", fmt.Errorf("missing authorization header")
+	}
+	const prefix = "bearer "
+	header := values[0]
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", fmt.Errorf("authorization header must be of the form %q", "bearer <token>")
+	}
+	return header[len(prefix):], nil
+}
+
+func audienceMatches(aud any, accepted []string) bool {
+	switch v := aud.(type) {
+	case string:
+		return containsValue(accepted, v)
+	case []any:
+		for _, a := range v {
+			if s, ok := a.(string); ok && containsValue(accepted, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsValue(values []string, want string) bool {
+	for _, v := range values {
+		if strings.TrimSpace(v) == want {
+			return true
+		}
+	}
+	return false
+}
+
+// peerHasVerifiedCertificate reports whether the incoming connection
+// already completed mTLS with a client certificate verified against the
+// server's configured CA pool.
+func peerHasVerifiedCertificate(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return false
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return false
+	}
+	return len(tlsInfo.State.VerifiedChains) > 0
+}
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Hubble
+
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckClaims(t *testing.T) {
+	tests := []struct {
+		name    string
+		claims  Claims
+		token   map[string]any
+		wantErr bool
+	}{
+		{
+			name:   "no constraints configured",
+			claims: Claims{},
+			token:  map[string]any{"iss": "anything"},
+		},
+		{
+			name:   "matching issuer",
+			claims: Claims{Issuer: "https://issuer.example.com"},
+			token:  map[string]any{"iss": "https://issuer.example.com"},
+		},
+		{
+			name:    "mismatched issuer",
+			claims:  Claims{Issuer: "https://issuer.example.com"},
+			token:   map[string]any{"iss": "https://evil.example.com"},
+			wantErr: true,
+		},
+		{
+			name:   "string audience matches",
+			claims: Claims{Audiences: []string{"hubble-relay"}},
+			token:  map[string]any{"aud": "hubble-relay"},
+		},
+		{
+			name:   "audience list matches one entry",
+			claims: Claims{Audiences: []string{"hubble-relay"}},
+			token:  map[string]any{"aud": []any{"other", "hubble-relay"}},
+		},
+		{
+			name:    "audience does not match",
+			claims:  Claims{Audiences: []string{"hubble-relay"}},
+			token:   map[string]any{"aud": "other"},
+			wantErr: true,
+		},
+		{
+			name:   "required claim value in allowed set",
+			claims: Claims{Required: map[string]string{"team": "platform, observability"}},
+			token:  map[string]any{"team": "observability"},
+		},
+		{
+			name:    "required claim value not in allowed set",
+			claims:  Claims{Required: map[string]string{"team": "platform, observability"}},
+			token:   map[string]any{"team": "billing"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Authenticator{claims: tt.claims}
+			err := a.checkClaims(tt.token)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAudienceMatches(t *testing.T) {
+	require.True(t, audienceMatches("hubble-relay", []string{"hubble-relay"}))
+	require.True(t, audienceMatches([]any{"a", "hubble-relay"}, []string{"hubble-relay"}))
+	require.False(t, audienceMatches("other", []string{"hubble-relay"}))
+	require.False(t, audienceMatches(42, []string{"hubble-relay"}))
+}
+
+func TestContainsValue(t *testing.T) {
+	require.True(t, containsValue([]string{"a", " b ", "c"}, "b"))
+	require.False(t, containsValue([]string{"a", "b"}, "c"))
+	require.False(t, containsValue(nil, "a"))
+}
@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Hubble
+
+package hubblecell
+
+import (
+	"fmt"
+
+	"github.com/cilium/cilium/pkg/hubble/auth"
+)
+
+// newAuthenticator builds an auth.Authenticator from cfg when JWT
+// authentication is enabled, wiring a JWKS-backed auth.KeySource from
+// hubble-jwt-jwks-url/hubble-jwt-jwks-file. It returns a nil Authenticator
+// and no error when hubble-jwt-auth-enabled is false, in which case the
+// Hubble gRPC server is started without JWT interceptors.
+func newAuthenticator(cfg config) (*auth.Authenticator, error) {
+	if !cfg.JWTAuthEnabled {
+		return nil, nil
+	}
+
+	keys, err := auth.NewJWKSKeySource(cfg.JWTJWKSURL, cfg.JWTJWKSFile, cfg.JWTRefreshInterval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize hubble-jwt-jwks-url/hubble-jwt-jwks-file: %w", err)
+	}
+
+	claims := auth.Claims{
+		Issuer:    cfg.JWTIssuer,
+		Audiences: cfg.JWTAudiences,
+		Required:  cfg.JWTRequiredClaims,
+	}
+
+	return auth.NewAuthenticator(keys, claims, auth.Mode(cfg.AuthMode)), nil
+}
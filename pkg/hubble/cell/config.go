@@ -5,8 +5,10 @@ package hubblecell
 
 import (
 	"fmt"
+	"net"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 	"google.golang.org/protobuf/types/known/fieldmaskpb"
@@ -40,7 +42,19 @@ type config struct {
 	SocketPath string `mapstructure:"hubble-socket-path"`
 
 	// ListenAddress specifies address for Hubble to listen to.
+	//
+	// Deprecated: use ListenAddresses instead. If set, this value is
+	// prepended to ListenAddresses by normalize().
 	ListenAddress string `mapstructure:"hubble-listen-address"`
+	// ListenAddresses specifies the addresses for Hubble to listen to. Each
+	// entry may optionally be suffixed with "|<network>" (e.g.
+	// "[::]:4244|tcp6") to override the default hubble-listen-network for
+	// that address.
+	ListenAddresses []string `mapstructure:"hubble-listen-addresses"`
+	// ListenNetwork specifies the default network for entries in
+	// ListenAddresses that do not carry an explicit "|<network>" suffix, one
+	// of "tcp", "tcp4" or "tcp6".
+	ListenNetwork string `mapstructure:"hubble-listen-network"`
 	// PreferIpv6 controls whether IPv6 or IPv4 addresses should be preferred
 	// for communication to agents, if both are available.
 	PreferIpv6 bool `mapstructure:"hubble-prefer-ipv6"`
@@ -58,6 +72,33 @@ type config struct {
 	// must contain PEM encoded data.
 	ServerTLSClientCAFiles []string `mapstructure:"hubble-tls-client-ca-files"`
 
+	// AuthMode specifies how mTLS and JWT authentication combine when both
+	// are configured: "and" requires both to succeed, "or" accepts either.
+	AuthMode string `mapstructure:"hubble-auth-mode"`
+	// JWTAuthEnabled enables JWT bearer-token authentication on the Hubble
+	// gRPC server.
+	JWTAuthEnabled bool `mapstructure:"hubble-jwt-auth-enabled"`
+	// JWTJWKSURL specifies the URL to periodically fetch the JSON Web Key
+	// Set used to verify bearer tokens from.
+	JWTJWKSURL string `mapstructure:"hubble-jwt-jwks-url"`
+	// JWTJWKSFile specifies a local JSON Web Key Set file to verify bearer
+	// tokens from, as an alternative to JWTJWKSURL.
+	JWTJWKSFile string `mapstructure:"hubble-jwt-jwks-file"`
+	// JWTIssuer specifies the required "iss" claim value.
+	JWTIssuer string `mapstructure:"hubble-jwt-issuer"`
+	// JWTAudiences specifies the accepted "aud" claim values; a token
+	// matching any one of them is accepted.
+	JWTAudiences []string `mapstructure:"hubble-jwt-audiences"`
+	// JWTRequiredClaims specifies additional claims that must be present on
+	// the token, keyed by claim name with a comma-separated list of allowed
+	// values.
+	JWTRequiredClaims map[string]string `mapstructure:"hubble-jwt-required-claims"`
+	// JWTRefreshInterval specifies how often the JWKS is re-fetched.
+	JWTRefreshInterval time.Duration `mapstructure:"hubble-jwt-refresh-interval"`
+	// JWTAllowInsecure allows JWT authentication to be enabled even when
+	// hubble-disable-tls is set, at the operator's own risk.
+	JWTAllowInsecure bool `mapstructure:"hubble-jwt-allow-insecure"`
+
 	// Metrics specifies enabled metrics and their configuration options.
 	Metrics []string `mapstructure:"hubble-metrics"`
 	// EnableOpenMetrics enables exporting hubble metrics in OpenMetrics
@@ -100,6 +141,63 @@ type config struct {
 	// ExportFieldmask specifies list of fields to log in exporter.
 	ExportFieldmask []string `mapstructure:"hubble-export-fieldmask"`
 
+	// OTLPEndpoint specifies the OTLP collector endpoint to export Hubble
+	// flows to, e.g. "otel-collector.observability.svc:4317". Leaving this
+	// empty disables the OTLP exporter.
+	OTLPEndpoint string `mapstructure:"hubble-otel-endpoint"`
+	// OTLPProtocol specifies the OTLP wire protocol to use, either "grpc" or
+	// "http-protobuf".
+	OTLPProtocol string `mapstructure:"hubble-otel-protocol"`
+	// OTLPHeaders specifies extra "key=value" headers to send with every
+	// OTLP request, e.g. for collector authentication.
+	OTLPHeaders []string `mapstructure:"hubble-otel-headers"`
+	// OTLPInsecure disables transport security for the OTLP exporter
+	// connection.
+	OTLPInsecure bool `mapstructure:"hubble-otel-insecure"`
+	// OTLPTLSCertFile specifies the path to the public key file for the OTLP
+	// exporter client certificate. The file must contain PEM encoded data.
+	OTLPTLSCertFile string `mapstructure:"hubble-otel-tls-cert-file"`
+	// OTLPTLSKeyFile specifies the path to the private key file for the OTLP
+	// exporter client certificate. The file must contain PEM encoded data.
+	OTLPTLSKeyFile string `mapstructure:"hubble-otel-tls-key-file"`
+	// OTLPTLSCAFile specifies the path to the CA certificate used to verify
+	// the OTLP collector's certificate. The file must contain PEM encoded
+	// data.
+	OTLPTLSCAFile string `mapstructure:"hubble-otel-tls-ca-file"`
+	// OTLPEncoding specifies how Hubble flows are mapped onto OTLP signals:
+	// "flow" (a dedicated flow log body), "log" (generic OTLP logs) or
+	// "span" (OTLP trace spans).
+	OTLPEncoding string `mapstructure:"hubble-otel-encoding"`
+	// OTLPBatchMaxSize specifies the maximum number of flows to accumulate
+	// before flushing a batch to the OTLP collector.
+	OTLPBatchMaxSize int `mapstructure:"hubble-otel-batch-max-size"`
+	// OTLPBatchTimeout specifies the maximum time to wait before flushing a
+	// partially filled batch to the OTLP collector.
+	OTLPBatchTimeout time.Duration `mapstructure:"hubble-otel-batch-timeout"`
+	// OTLPFieldmask specifies list of fields to include in exported flows,
+	// mirroring hubble-export-fieldmask.
+	OTLPFieldmask []string `mapstructure:"hubble-otel-fieldmask"`
+	// OTLPAllowlist specifies allow list filter used by the OTLP exporter.
+	OTLPAllowlist []*flowpb.FlowFilter `mapstructure:"hubble-otel-allowlist"`
+	// OTLPDenylist specifies deny list filter used by the OTLP exporter.
+	OTLPDenylist []*flowpb.FlowFilter `mapstructure:"hubble-otel-denylist"`
+
+	// ReproCapturePath specifies the filepath to write the NDJSON
+	// reproducer capture file to. Leaving this empty disables capture.
+	ReproCapturePath string `mapstructure:"hubble-repro-capture-path"`
+	// ReproCaptureMaxEvents specifies the maximum number of events to write
+	// to the reproducer capture file; once reached, further events are not
+	// captured. 0 means unlimited.
+	ReproCaptureMaxEvents int `mapstructure:"hubble-repro-capture-max-events"`
+	// ReproCaptureFilter restricts which flows are written to the
+	// reproducer capture file.
+	ReproCaptureFilter []*flowpb.FlowFilter `mapstructure:"hubble-repro-capture-filter"`
+	// ReproCaptureIncludePayload specifies whether to include L7 message
+	// contents of captured flows, which may contain sensitive data. The raw
+	// monitor event itself is always captured, since replay needs it. This
+	// only widens or narrows what's kept on the parsed flow side.
+	ReproCaptureIncludePayload bool `mapstructure:"hubble-repro-capture-include-payload"`
+
 	// EnableRecorderAPI specifies if the Hubble Recorder API should be served.
 	EnableRecorderAPI bool `mapstructure:"enable-hubble-recorder-api"`
 	// RecorderStoragePath specifies the directory in which pcap files created
@@ -120,11 +218,23 @@ var defaultConfig = config{
 	SocketPath: hubbleDefaults.SocketPath,
 	// Hubble TCP server configuration
 	ListenAddress:          "",
+	ListenAddresses:        []string{},
+	ListenNetwork:          "tcp",
 	PreferIpv6:             false,
 	DisableServerTLS:       false,
 	ServerTLSCertFile:      "",
 	ServerTLSKeyFile:       "",
 	ServerTLSClientCAFiles: []string{},
+	// Hubble JWT authentication configuration
+	AuthMode:           "or",
+	JWTAuthEnabled:     false,
+	JWTJWKSURL:         "",
+	JWTJWKSFile:        "",
+	JWTIssuer:          "",
+	JWTAudiences:       []string{},
+	JWTRequiredClaims:  map[string]string{},
+	JWTRefreshInterval: 5 * time.Minute,
+	JWTAllowInsecure:   false,
 	// Hubble metrics configuration
 	Metrics:           []string{},
 	EnableOpenMetrics: false,
@@ -143,6 +253,25 @@ var defaultConfig = config{
 	ExportAllowlist:        []*flowpb.FlowFilter{},
 	ExportDenylist:         []*flowpb.FlowFilter{},
 	ExportFieldmask:        []string{},
+	// Hubble OTLP export configuration
+	OTLPEndpoint:     "",
+	OTLPProtocol:     "grpc",
+	OTLPHeaders:      []string{},
+	OTLPInsecure:     false,
+	OTLPTLSCertFile:  "",
+	OTLPTLSKeyFile:   "",
+	OTLPTLSCAFile:    "",
+	OTLPEncoding:     "flow",
+	OTLPBatchMaxSize: 512,
+	OTLPBatchTimeout: 5 * time.Second,
+	OTLPFieldmask:    []string{},
+	OTLPAllowlist:    []*flowpb.FlowFilter{},
+	OTLPDenylist:     []*flowpb.FlowFilter{},
+	// Hubble reproducer capture configuration
+	ReproCapturePath:           "",
+	ReproCaptureMaxEvents:      0,
+	ReproCaptureFilter:         []*flowpb.FlowFilter{},
+	ReproCaptureIncludePayload: false,
 	// Hubble recorder configuration
 	EnableRecorderAPI:     true,
 	RecorderStoragePath:   hubbleDefaults.RecorderStoragePath,
@@ -164,12 +293,24 @@ func (def config) Flags(flags *pflag.FlagSet) {
 	// Hubble local server configuration
 	flags.String("hubble-socket-path", def.SocketPath, "Set hubble's socket path to listen for connections")
 	// Hubble TCP server configuration
-	flags.String("hubble-listen-address", def.ListenAddress, `An additional address for Hubble server to listen to, e.g. ":4244"`)
+	flags.String("hubble-listen-address", def.ListenAddress, `An additional address for Hubble server to listen to, e.g. ":4244" (deprecated: use hubble-listen-addresses instead)`)
+	flags.StringSlice("hubble-listen-addresses", def.ListenAddresses, `Addresses for Hubble server to listen to, e.g. ":4244". An entry may be suffixed with "|<network>" (e.g. "[::]:4244|tcp6") to override hubble-listen-network for that address.`)
+	flags.String("hubble-listen-network", def.ListenNetwork, "Default network for hubble-listen-addresses entries without an explicit network suffix: tcp, tcp4 or tcp6.")
 	flags.Bool("hubble-prefer-ipv6", def.PreferIpv6, "Prefer IPv6 addresses for announcing nodes when both address types are available.")
 	flags.Bool("hubble-disable-tls", def.DisableServerTLS, "Allow Hubble server to run on the given listen address without TLS.")
 	flags.String("hubble-tls-cert-file", def.ServerTLSCertFile, "Path to the public key file for the Hubble server. The file must contain PEM encoded data.")
 	flags.String("hubble-tls-key-file", def.ServerTLSKeyFile, "Path to the private key file for the Hubble server. The file must contain PEM encoded data.")
 	flags.StringSlice("hubble-tls-client-ca-files", def.ServerTLSClientCAFiles, "Paths to one or more public key files of client CA certificates to use for TLS with mutual authentication (mTLS). The files must contain PEM encoded data. When provided, this option effectively enables mTLS.")
+	// Hubble JWT authentication configuration
+	flags.String("hubble-auth-mode", def.AuthMode, "How mTLS and JWT authentication combine when both are configured: \"and\" requires both to succeed, \"or\" accepts either.")
+	flags.Bool("hubble-jwt-auth-enabled", def.JWTAuthEnabled, "Enable JWT bearer-token authentication on the Hubble gRPC server.")
+	flags.String("hubble-jwt-jwks-url", def.JWTJWKSURL, "URL to periodically fetch the JSON Web Key Set used to verify bearer tokens from.")
+	flags.String("hubble-jwt-jwks-file", def.JWTJWKSFile, "Local JSON Web Key Set file used to verify bearer tokens from, as an alternative to hubble-jwt-jwks-url.")
+	flags.String("hubble-jwt-issuer", def.JWTIssuer, "Required \"iss\" claim value for Hubble JWT authentication.")
+	flags.StringSlice("hubble-jwt-audiences", def.JWTAudiences, "Accepted \"aud\" claim values for Hubble JWT authentication; a token matching any one of them is accepted.")
+	flags.StringToString("hubble-jwt-required-claims", def.JWTRequiredClaims, "Additional claims that must be present on the token, keyed by claim name with a comma-separated list of allowed values.")
+	flags.Duration("hubble-jwt-refresh-interval", def.JWTRefreshInterval, "How often the JWKS used for Hubble JWT authentication is re-fetched.")
+	flags.Bool("hubble-jwt-allow-insecure", def.JWTAllowInsecure, "Allow JWT authentication to be enabled even when hubble-disable-tls is set.")
 	flags.StringSlice("hubble-metrics", def.Metrics, "List of Hubble metrics to enable.")
 	flags.Bool("enable-hubble-open-metrics", def.EnableOpenMetrics, "Enable exporting hubble metrics in OpenMetrics format")
 	// Hubble metrics server configuration
@@ -187,6 +328,25 @@ func (def config) Flags(flags *pflag.FlagSet) {
 	flags.StringSlice("hubble-export-allowlist", []string{}, "Specify allowlist as JSON encoded FlowFilters to Hubble exporter.")
 	flags.StringSlice("hubble-export-denylist", []string{}, "Specify denylist as JSON encoded FlowFilters to Hubble exporter.")
 	flags.StringSlice("hubble-export-fieldmask", def.ExportFieldmask, "Specify list of fields to use for field mask in Hubble exporter.")
+	// Hubble OTLP export configuration
+	flags.String("hubble-otel-endpoint", def.OTLPEndpoint, "OTLP collector endpoint to export Hubble flows to, e.g. \"otel-collector.observability.svc:4317\". Disabled when empty.")
+	flags.String("hubble-otel-protocol", def.OTLPProtocol, "OTLP wire protocol to use: grpc or http-protobuf.")
+	flags.StringSlice("hubble-otel-headers", def.OTLPHeaders, "Extra \"key=value\" headers to send with every OTLP request.")
+	flags.Bool("hubble-otel-insecure", def.OTLPInsecure, "Disable transport security for the OTLP exporter connection.")
+	flags.String("hubble-otel-tls-cert-file", def.OTLPTLSCertFile, "Path to the public key file for the OTLP exporter client certificate. The file must contain PEM encoded data.")
+	flags.String("hubble-otel-tls-key-file", def.OTLPTLSKeyFile, "Path to the private key file for the OTLP exporter client certificate. The file must contain PEM encoded data.")
+	flags.String("hubble-otel-tls-ca-file", def.OTLPTLSCAFile, "Path to the CA certificate used to verify the OTLP collector's certificate. The file must contain PEM encoded data.")
+	flags.String("hubble-otel-encoding", def.OTLPEncoding, "How Hubble flows are mapped onto OTLP signals: flow, log or span.")
+	flags.Int("hubble-otel-batch-max-size", def.OTLPBatchMaxSize, "Maximum number of flows to accumulate before flushing a batch to the OTLP collector.")
+	flags.Duration("hubble-otel-batch-timeout", def.OTLPBatchTimeout, "Maximum time to wait before flushing a partially filled batch to the OTLP collector.")
+	flags.StringSlice("hubble-otel-fieldmask", def.OTLPFieldmask, "Specify list of fields to use for field mask in the Hubble OTLP exporter.")
+	flags.StringSlice("hubble-otel-allowlist", []string{}, "Specify allowlist as JSON encoded FlowFilters to the Hubble OTLP exporter.")
+	flags.StringSlice("hubble-otel-denylist", []string{}, "Specify denylist as JSON encoded FlowFilters to the Hubble OTLP exporter.")
+	// Hubble reproducer capture configuration
+	flags.String("hubble-repro-capture-path", def.ReproCapturePath, "Filepath to write raw monitor events, parsed flows and exporter filter decisions to, for later replay via `hubble reproduce`. Disabled when empty.")
+	flags.Int("hubble-repro-capture-max-events", def.ReproCaptureMaxEvents, "Maximum number of events to write to the reproducer capture file; further events are not captured once it is reached. 0 means unlimited.")
+	flags.StringSlice("hubble-repro-capture-filter", []string{}, "Specify a FlowFilter as JSON encoded entries restricting which flows are written to the reproducer capture file.")
+	flags.Bool("hubble-repro-capture-include-payload", def.ReproCaptureIncludePayload, "Include L7 message contents of captured flows in the reproducer capture file. The raw monitor event is captured either way. Off by default since payloads may contain sensitive data.")
 	// Hubble recorder configuration
 	flags.Bool("enable-hubble-recorder-api", def.EnableRecorderAPI, "Enable the Hubble recorder API")
 	flags.String("hubble-recorder-storage-path", def.RecorderStoragePath, "Directory in which pcap files created via the Hubble Recorder API are stored")
@@ -198,15 +358,111 @@ func (cfg *config) normalize() {
 	if cfg.EventQueueSize == 0 {
 		cfg.EventQueueSize = getDefaultMonitorQueueSize(runtime.NumCPU())
 	}
+
+	// hubble-listen-address is deprecated in favor of hubble-listen-addresses.
+	// For one release, prepend it so both flags keep working together.
+	if cfg.ListenAddress != "" {
+		cfg.ListenAddresses = append([]string{cfg.ListenAddress}, cfg.ListenAddresses...)
+	}
+}
+
+// splitListenAddress splits an "addr|network" entry of hubble-listen-addresses
+// into its address and network, falling back to def when no network suffix
+// is present.
+func splitListenAddress(raw, def string) (addr, network string) {
+	if a, n, ok := strings.Cut(raw, "|"); ok {
+		return a, n
+	}
+	return raw, def
+}
+
+// allIPv4 reports whether every entry in addrs resolves to an IPv4-only
+// network (tcp4, or a literal IPv4 host with no explicit network).
+func allIPv4(addrs []string) bool {
+	for _, raw := range addrs {
+		addr, network := splitListenAddress(raw, "tcp")
+		if network == "tcp6" {
+			return false
+		}
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			host = addr
+		}
+		if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+			return false
+		}
+	}
+	return true
 }
 
 func (cfg config) validate() error {
+	seen := make(map[string]struct{}, len(cfg.ListenAddresses))
+	for _, raw := range cfg.ListenAddresses {
+		addr, network := splitListenAddress(raw, cfg.ListenNetwork)
+		switch network {
+		case "tcp", "tcp4", "tcp6":
+		default:
+			return fmt.Errorf("hubble-listen-addresses entry %q has invalid network %q: must be tcp, tcp4 or tcp6", raw, network)
+		}
+		if _, ok := seen[addr]; ok {
+			return fmt.Errorf("hubble-listen-addresses contains duplicate address %q", addr)
+		}
+		seen[addr] = struct{}{}
+	}
+
 	if fm := cfg.ExportFieldmask; len(fm) > 0 {
 		_, err := fieldmaskpb.New(&flowpb.Flow{}, fm...)
 		if err != nil {
 			return fmt.Errorf("hubble-export-fieldmask contains invalid fieldmask '%v': %w", fm, err)
 		}
 	}
+	if fm := cfg.OTLPFieldmask; len(fm) > 0 {
+		_, err := fieldmaskpb.New(&flowpb.Flow{}, fm...)
+		if err != nil {
+			return fmt.Errorf("hubble-otel-fieldmask contains invalid fieldmask '%v': %w", fm, err)
+		}
+	}
+	if cfg.JWTAuthEnabled {
+		if cfg.JWTJWKSURL == "" && cfg.JWTJWKSFile == "" {
+			return fmt.Errorf("hubble-jwt-auth-enabled requires hubble-jwt-jwks-url or hubble-jwt-jwks-file to be set")
+		}
+		if cfg.JWTJWKSURL != "" && cfg.JWTJWKSFile != "" {
+			return fmt.Errorf("hubble-jwt-jwks-url and hubble-jwt-jwks-file are mutually exclusive")
+		}
+		if cfg.JWTIssuer == "" {
+			return fmt.Errorf("hubble-jwt-auth-enabled requires hubble-jwt-issuer to be set")
+		}
+		if cfg.DisableServerTLS && !cfg.JWTAllowInsecure {
+			return fmt.Errorf("hubble-jwt-auth-enabled cannot be combined with hubble-disable-tls unless hubble-jwt-allow-insecure is set")
+		}
+		if cfg.JWTRefreshInterval <= 0 {
+			return fmt.Errorf("hubble-jwt-refresh-interval must be positive, got %s", cfg.JWTRefreshInterval)
+		}
+		switch cfg.AuthMode {
+		case "and", "or":
+		default:
+			return fmt.Errorf("hubble-auth-mode must be one of and, or, got %q", cfg.AuthMode)
+		}
+		if cfg.AuthMode == "and" && len(cfg.ServerTLSClientCAFiles) == 0 {
+			return fmt.Errorf("hubble-auth-mode=and requires hubble-tls-client-ca-files to be set, otherwise no client certificate can ever be verified and every request is rejected")
+		}
+	}
+	if cfg.OTLPEndpoint != "" {
+		switch cfg.OTLPProtocol {
+		case "grpc", "http-protobuf":
+		default:
+			return fmt.Errorf("hubble-otel-protocol must be one of grpc, http-protobuf, got %q", cfg.OTLPProtocol)
+		}
+		switch cfg.OTLPEncoding {
+		case "flow", "log", "span":
+		default:
+			return fmt.Errorf("hubble-otel-encoding must be one of flow, log, span, got %q", cfg.OTLPEncoding)
+		}
+	}
+	if cfg.ReproCaptureMaxEvents < 0 {
+		return fmt.Errorf("hubble-repro-capture-max-events must not be negative, got %d", cfg.ReproCaptureMaxEvents)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Hubble
+
+package hubblecell
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitListenAddress(t *testing.T) {
+	addr, network := splitListenAddress(":4244", "tcp")
+	require.Equal(t, ":4244", addr)
+	require.Equal(t, "tcp", network)
+
+	addr, network = splitListenAddress("[::]:4244|tcp6", "tcp")
+	require.Equal(t, "[::]:4244", addr)
+	require.Equal(t, "tcp6", network)
+}
+
+func TestAllIPv4(t *testing.T) {
+	require.True(t, allIPv4([]string{"10.0.0.1:4244", "127.0.0.1:4245"}))
+	require.False(t, allIPv4([]string{"10.0.0.1:4244", "[::1]:4245"}))
+	require.False(t, allIPv4([]string{"[::]:4244|tcp6"}))
+	require.True(t, allIPv4([]string{":4244"}))
+}
+
+func TestValidateRejectsDuplicateListenAddresses(t *testing.T) {
+	cfg := defaultConfig
+	cfg.ListenAddresses = []string{":4244", ":4244"}
+	err := cfg.validate()
+	require.ErrorContains(t, err, "duplicate")
+}
+
+func TestValidateRejectsInvalidListenNetwork(t *testing.T) {
+	cfg := defaultConfig
+	cfg.ListenAddresses = []string{":4244|udp"}
+	err := cfg.validate()
+	require.ErrorContains(t, err, "invalid network")
+}
+
+func TestValidateAcceptsDistinctListenAddresses(t *testing.T) {
+	cfg := defaultConfig
+	cfg.ListenAddresses = []string{":4244", ":4245|tcp6"}
+	require.NoError(t, cfg.validate())
+}
+
+func TestValidateRejectsNonPositiveJWTRefreshInterval(t *testing.T) {
+	cfg := defaultConfig
+	cfg.JWTAuthEnabled = true
+	cfg.JWTJWKSURL = "https://issuer.example.com/jwks.json"
+	cfg.JWTIssuer = "https://issuer.example.com"
+	cfg.JWTRefreshInterval = 0
+	err := cfg.validate()
+	require.ErrorContains(t, err, "hubble-jwt-refresh-interval")
+}
+
+func TestValidateRejectsAuthModeAndWithoutClientCA(t *testing.T) {
+	cfg := defaultConfig
+	cfg.JWTAuthEnabled = true
+	cfg.JWTJWKSURL = "https://issuer.example.com/jwks.json"
+	cfg.JWTIssuer = "https://issuer.example.com"
+	cfg.AuthMode = "and"
+	cfg.ServerTLSClientCAFiles = nil
+	err := cfg.validate()
+	require.ErrorContains(t, err, "hubble-auth-mode=and")
+}
+
+func TestValidateAcceptsAuthModeAndWithClientCA(t *testing.T) {
+	cfg := defaultConfig
+	cfg.JWTAuthEnabled = true
+	cfg.JWTJWKSURL = "https://issuer.example.com/jwks.json"
+	cfg.JWTIssuer = "https://issuer.example.com"
+	cfg.AuthMode = "and"
+	cfg.ServerTLSClientCAFiles = []string{"ca.pem"}
+	require.NoError(t, cfg.validate())
+}
@@ -0,0 +1,189 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Hubble
+
+package hubblecell
+
+import (
+	"fmt"
+	"math/bits"
+	"net"
+	"strings"
+
+	monitorAPI "github.com/cilium/cilium/pkg/monitor/api"
+)
+
+// Severity classifies a Diagnostic by how the hive cell should react to it:
+// an Error prevents the cell from starting, while a Warning or Hint is only
+// surfaced to the operator.
+type Severity string
+
+const (
+	// SeverityError indicates a configuration that cannot be started
+	// safely; the hive cell refuses to start when any are present.
+	SeverityError Severity = "error"
+	// SeverityWarning indicates a configuration that is valid but likely
+	// unintended.
+	SeverityWarning Severity = "warning"
+	// SeverityHint indicates a suggestion for a better configuration with
+	// no functional impact.
+	SeverityHint Severity = "hint"
+)
+
+// Diagnostic describes a single finding from config.Diagnose, consumed by
+// startup logging and the /healthz/config endpoint.
+type Diagnostic struct {
+	Severity   Severity `json:"severity"`
+	Field      string   `json:"field"`
+	Message    string   `json:"message"`
+	Suggestion string   `json:"suggestion,omitempty"`
+}
+
+// Diagnose runs a set of best-effort checks over cfg beyond the structural
+// validation already performed by validate(), returning warnings and hints
+// an operator should act on. Unlike validate(), Diagnose never returns an
+// error: the hive cell logs every diagnostic and only refuses to start on
+// one with SeverityError.
+func (cfg config) Diagnose() []Diagnostic {
+	var diags []Diagnostic
+
+	if cfg.DisableServerTLS {
+		for _, raw := range cfg.ListenAddresses {
+			addr, _ := splitListenAddress(raw, cfg.ListenNetwork)
+			if !isLoopbackAddress(addr) {
+				diags = append(diags, Diagnostic{
+					Severity:   SeverityWarning,
+					Field:      "hubble-disable-tls",
+					Message:    fmt.Sprintf("TLS is disabled on non-loopback address %q", addr),
+					Suggestion: "restrict hubble-listen-addresses to loopback addresses, or re-enable TLS with hubble-tls-cert-file/hubble-tls-key-file",
+				})
+			}
+		}
+	}
+
+	if cfg.MetricsServer != "" && len(cfg.Metrics) == 0 {
+		diags = append(diags, Diagnostic{
+			Severity:   SeverityWarning,
+			Field:      "hubble-metrics-server",
+			Message:    "hubble-metrics-server is set but hubble-metrics is empty, so no metrics will be served",
+			Suggestion: "set hubble-metrics to the list of metrics to enable",
+		})
+	}
+	if cfg.MetricsServer == "" && len(cfg.Metrics) > 0 {
+		diags = append(diags, Diagnostic{
+			Severity:   SeverityWarning,
+			Field:      "hubble-metrics",
+			Message:    "hubble-metrics is set but hubble-metrics-server is empty, so metrics cannot be scraped",
+			Suggestion: "set hubble-metrics-server to an address for the metrics server to listen on",
+		})
+	}
+
+	if n := cfg.EventBufferCapacity; n != 0 && !isPow2Minus1(n) {
+		diags = append(diags, Diagnostic{
+			Severity:   SeverityWarning,
+			Field:      "hubble-event-buffer-capacity",
+			Message:    fmt.Sprintf("hubble-event-buffer-capacity=%d is not one less than a power of two", n),
+			Suggestion: "use a value such as 4095 or 65535 (2^n - 1) for optimal ring buffer utilization",
+		})
+	}
+
+	if unknown := unknownMonitorEvents(cfg.MonitorEvents); len(unknown) > 0 {
+		diags = append(diags, Diagnostic{
+			Severity:   SeverityError,
+			Field:      "hubble-monitor-events",
+			Message:    fmt.Sprintf("unknown monitor event type(s): %s", strings.Join(unknown, ", ")),
+			Suggestion: fmt.Sprintf("use one of: %s", strings.Join(monitorAPI.AllMessageTypeNames(), ", ")),
+		})
+	}
+
+	if len(cfg.ExportFieldmask) > 0 && len(cfg.Metrics) > 0 {
+		if missing := missingMetricsLabelFields(cfg.ExportFieldmask); len(missing) > 0 {
+			diags = append(diags, Diagnostic{
+				Severity:   SeverityHint,
+				Field:      "hubble-export-fieldmask",
+				Message:    fmt.Sprintf("hubble-export-fieldmask omits field(s) %s used as labels by enabled hubble-metrics", strings.Join(missing, ", ")),
+				Suggestion: "add the missing field(s) to hubble-export-fieldmask, or drop the corresponding metric labels",
+			})
+		}
+	}
+
+	diags = append(diags, tlsPairDiagnostics("hubble-tls", cfg.ServerTLSCertFile, cfg.ServerTLSKeyFile)...)
+	diags = append(diags, tlsPairDiagnostics("hubble-metrics-server-tls", cfg.MetricsServerTLSCertFile, cfg.MetricsServerTLSKeyFile)...)
+
+	if cfg.PreferIpv6 && len(cfg.ListenAddresses) > 0 && allIPv4(cfg.ListenAddresses) {
+		diags = append(diags, Diagnostic{
+			Severity:   SeverityWarning,
+			Field:      "hubble-prefer-ipv6",
+			Message:    fmt.Sprintf("hubble-prefer-ipv6 is set but hubble-listen-addresses only contains IPv4 addresses: %s", strings.Join(cfg.ListenAddresses, ", ")),
+			Suggestion: "add an IPv6 or dual-stack entry to hubble-listen-addresses, or drop hubble-prefer-ipv6",
+		})
+	}
+
+	return diags
+}
+
+// tlsPairDiagnostics flags a cert/key pair where exactly one side is set,
+// which always fails to start a TLS listener.
+func tlsPairDiagnostics(prefix, certFile, keyFile string) []Diagnostic {
+	if (certFile == "") == (keyFile == "") {
+		return nil
+	}
+	return []Diagnostic{{
+		Severity:   SeverityError,
+		Field:      prefix + "-cert-file",
+		Message:    fmt.Sprintf("%s-cert-file and %s-key-file must both be set, or both left empty", prefix, prefix),
+		Suggestion: fmt.Sprintf("set the missing one of %s-cert-file / %s-key-file", prefix, prefix),
+	}}
+}
+
+func isLoopbackAddress(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// isPow2Minus1 reports whether n is of the form 2^k - 1 for some k >= 1.
+func isPow2Minus1(n int) bool {
+	return n > 0 && bits.OnesCount(uint(n)) == bits.Len(uint(n))
+}
+
+// metricsLabelFields lists the top-level Flow fields commonly used as
+// metric labels, e.g. by the "source"/"destination" hubble metrics context
+// options. It is intentionally conservative: a field mask missing one of
+// these only ever produces empty metric labels, never a startup failure.
+var metricsLabelFields = []string{"source", "destination", "verdict", "l7"}
+
+// missingMetricsLabelFields returns the entries of metricsLabelFields not
+// present in mask.
+func missingMetricsLabelFields(mask []string) []string {
+	present := make(map[string]struct{}, len(mask))
+	for _, f := range mask {
+		present[f] = struct{}{}
+	}
+	var missing []string
+	for _, f := range metricsLabelFields {
+		if _, ok := present[f]; !ok {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
+
+func unknownMonitorEvents(events []string) []string {
+	known := make(map[string]struct{}, len(monitorAPI.AllMessageTypeNames()))
+	for _, name := range monitorAPI.AllMessageTypeNames() {
+		known[name] = struct{}{}
+	}
+	var unknown []string
+	for _, e := range events {
+		if _, ok := known[e]; !ok {
+			unknown = append(unknown, e)
+		}
+	}
+	return unknown
+}
@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Hubble
+
+package hubblecell
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPow2Minus1(t *testing.T) {
+	require.True(t, isPow2Minus1(1))
+	require.True(t, isPow2Minus1(3))
+	require.True(t, isPow2Minus1(4095))
+	require.True(t, isPow2Minus1(65535))
+	require.False(t, isPow2Minus1(0))
+	require.False(t, isPow2Minus1(5))
+	require.False(t, isPow2Minus1(1000))
+}
+
+func TestTLSPairDiagnostics(t *testing.T) {
+	require.Empty(t, tlsPairDiagnostics("hubble-tls", "", ""))
+	require.Empty(t, tlsPairDiagnostics("hubble-tls", "cert.pem", "key.pem"))
+
+	diags := tlsPairDiagnostics("hubble-tls", "cert.pem", "")
+	require.Len(t, diags, 1)
+	require.Equal(t, SeverityError, diags[0].Severity)
+
+	diags = tlsPairDiagnostics("hubble-tls", "", "key.pem")
+	require.Len(t, diags, 1)
+	require.Equal(t, SeverityError, diags[0].Severity)
+}
+
+func TestDiagnoseFlagsUnknownMonitorEvent(t *testing.T) {
+	cfg := defaultConfig
+	cfg.MonitorEvents = []string{"not-a-real-event-type"}
+
+	diags := cfg.Diagnose()
+	var found bool
+	for _, d := range diags {
+		if d.Field == "hubble-monitor-events" {
+			found = true
+			require.Equal(t, SeverityError, d.Severity)
+		}
+	}
+	require.True(t, found, "expected a diagnostic for the unknown monitor event")
+}
+
+func TestDiagnoseFlagsMismatchedMetricsServer(t *testing.T) {
+	cfg := defaultConfig
+	cfg.MetricsServer = ":9965"
+	cfg.Metrics = nil
+
+	diags := cfg.Diagnose()
+	var found bool
+	for _, d := range diags {
+		if d.Field == "hubble-metrics-server" {
+			found = true
+		}
+	}
+	require.True(t, found)
+}
+
+func TestDiagnoseFlagsPreferIpv6WithIPv4OnlyAddresses(t *testing.T) {
+	cfg := defaultConfig
+	cfg.PreferIpv6 = true
+	cfg.ListenAddresses = []string{"127.0.0.1:4244"}
+
+	diags := cfg.Diagnose()
+	var found bool
+	for _, d := range diags {
+		if d.Field == "hubble-prefer-ipv6" {
+			found = true
+			require.Equal(t, SeverityWarning, d.Severity)
+		}
+	}
+	require.True(t, found, "expected a diagnostic for hubble-prefer-ipv6 with IPv4-only listen addresses")
+}
+
+func TestDiagnoseDoesNotFlagPreferIpv6WithIPv6Address(t *testing.T) {
+	cfg := defaultConfig
+	cfg.PreferIpv6 = true
+	cfg.ListenAddresses = []string{"[::1]:4244"}
+
+	diags := cfg.Diagnose()
+	for _, d := range diags {
+		require.NotEqual(t, "hubble-prefer-ipv6", d.Field)
+	}
+}
+
+func TestMissingMetricsLabelFields(t *testing.T) {
+	require.ElementsMatch(t, []string{"destination", "verdict", "l7"}, missingMetricsLabelFields([]string{"source"}))
+	require.Empty(t, missingMetricsLabelFields([]string{"source", "destination", "verdict", "l7"}))
+}
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Hubble
+
+package hubblecell
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// logDiagnostics logs every diagnostic from cfg.Diagnose() at a level
+// matching its severity, and returns an error if any of them is a
+// SeverityError, so the hive cell can refuse to start.
+func logDiagnostics(logger *slog.Logger, cfg config) error {
+	var firstErr error
+	for _, d := range cfg.Diagnose() {
+		attrs := []any{"field", d.Field, "message", d.Message}
+		if d.Suggestion != "" {
+			attrs = append(attrs, "suggestion", d.Suggestion)
+		}
+		switch d.Severity {
+		case SeverityError:
+			logger.Error("hubble config diagnostic", attrs...)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %s", d.Field, d.Message)
+			}
+		case SeverityWarning:
+			logger.Warn("hubble config diagnostic", attrs...)
+		default:
+			logger.Info("hubble config diagnostic", attrs...)
+		}
+	}
+	if firstErr != nil {
+		return fmt.Errorf("hubble config has invalid settings, refusing to start: %w", firstErr)
+	}
+	return nil
+}
+
+// configHealthzHandler serves the non-fatal diagnostics (warnings and
+// hints) from cfg.Diagnose() as JSON on /healthz/config, so that `cilium
+// status` and CI can consume them without re-implementing the checks.
+func configHealthzHandler(cfg config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var diags []Diagnostic
+		for _, d := range cfg.Diagnose() {
+			if d.Severity == SeverityError {
+				continue
+			}
+			diags = append(diags, d)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(diags); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Hubble
+
+package hubblecell
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	flowpb "github.com/cilium/cilium/api/v1/flow"
+	"github.com/cilium/cilium/pkg/hubble/exporter/otlpexporter"
+)
+
+// newOTLPExporter builds an otlpexporter.Exporter from cfg when an OTLP
+// endpoint is configured. It returns a nil Exporter and no error when
+// hubble-otel-endpoint is empty, in which case no OTLP sink is registered
+// alongside the file exporter.
+func newOTLPExporter(cfg config) (*otlpexporter.Exporter, error) {
+	if cfg.OTLPEndpoint == "" {
+		return nil, nil
+	}
+
+	headers, err := parseHeaders(cfg.OTLPHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hubble-otel-headers: %w", err)
+	}
+
+	var mask *fieldmaskpb.FieldMask
+	if len(cfg.OTLPFieldmask) > 0 {
+		mask, err = fieldmaskpb.New(&flowpb.Flow{}, cfg.OTLPFieldmask...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hubble-otel-fieldmask: %w", err)
+		}
+	}
+
+	return otlpexporter.NewExporter(otlpexporter.Options{
+		Endpoint:     cfg.OTLPEndpoint,
+		Protocol:     cfg.OTLPProtocol,
+		Headers:      headers,
+		Insecure:     cfg.OTLPInsecure,
+		TLSCertFile:  cfg.OTLPTLSCertFile,
+		TLSKeyFile:   cfg.OTLPTLSKeyFile,
+		TLSCAFile:    cfg.OTLPTLSCAFile,
+		Encoding:     otlpexporter.Encoding(cfg.OTLPEncoding),
+		BatchMaxSize: cfg.OTLPBatchMaxSize,
+		BatchTimeout: cfg.OTLPBatchTimeout,
+		Fieldmask:    mask,
+		Allowlist:    cfg.OTLPAllowlist,
+		Denylist:     cfg.OTLPDenylist,
+	})
+}
+
+// parseHeaders parses "key=value" entries from hubble-otel-headers into a
+// map, mirroring the syntax used by hubble-jwt-required-claims.
+func parseHeaders(raw []string) (map[string]string, error) {
+	headers := make(map[string]string, len(raw))
+	for _, entry := range raw {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("entry %q is not of the form key=value", entry)
+		}
+		headers[key] = value
+	}
+	return headers, nil
+}
@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Hubble
+
+package hubblecell
+
+import (
+	"github.com/cilium/cilium/pkg/hubble/observer/reproducer"
+)
+
+// newReproCapture builds a reproducer.Capture from cfg when a capture path
+// is configured. It returns a nil Capture and no error when
+// hubble-repro-capture-path is empty, in which case no capture is taken.
+// Rotation reuses the same hubble-export-file-max-size-mb/max-backups/
+// compress settings as the file exporter, rather than introducing a
+// separate set of knobs for what is already the same on-disk concern.
+func newReproCapture(cfg config) (*reproducer.Capture, error) {
+	if cfg.ReproCapturePath == "" {
+		return nil, nil
+	}
+
+	return reproducer.NewCapture(reproducer.Options{
+		Path:           cfg.ReproCapturePath,
+		MaxEvents:      cfg.ReproCaptureMaxEvents,
+		Filter:         cfg.ReproCaptureFilter,
+		IncludePayload: cfg.ReproCaptureIncludePayload,
+		MaxSizeMB:      cfg.ExportFileMaxSizeMB,
+		MaxBackups:     cfg.ExportFileMaxBackups,
+		Compress:       cfg.ExportFileCompress,
+	})
+}
@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Hubble
+
+package otlpexporter
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// newClient builds the otlpClient matching opts.Protocol, connected to
+// opts.Endpoint with the configured TLS material and headers.
+func newClient(opts Options) (otlpClient, error) {
+	switch opts.Protocol {
+	case "grpc":
+		return newGRPCClient(opts)
+	case "http-protobuf":
+		return newHTTPClient(opts)
+	default:
+		return nil, fmt.Errorf("otlp exporter: unsupported protocol %q", opts.Protocol)
+	}
+}
+
+func buildTLSConfig(opts Options) (*tls.Config, error) {
+	if opts.Insecure {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{}
+	if opts.TLSCertFile != "" || opts.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.TLSCertFile, opts.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load hubble-otel-tls-cert-file/hubble-otel-tls-key-file: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	if opts.TLSCAFile != "" {
+		pem, err := os.ReadFile(opts.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read hubble-otel-tls-ca-file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("hubble-otel-tls-ca-file does not contain a valid PEM certificate")
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// grpcClient is an otlpClient that speaks OTLP/gRPC.
+type grpcClient struct {
+	conn    *grpc.ClientConn
+	trace   coltracepb.TraceServiceClient
+	logs    collogspb.LogsServiceClient
+	headers metadata.MD
+}
+
+func newGRPCClient(opts Options) (*grpcClient, error) {
+	tlsConfig, err := buildTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var creds credentials.TransportCredentials
+	if tlsConfig == nil {
+		creds = insecure.NewCredentials()
+	} else {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	conn, err := grpc.NewClient(opts.Endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial hubble-otel-endpoint %q: %w", opts.Endpoint, err)
+	}
+
+	return &grpcClient{
+		conn:    conn,
+		trace:   coltracepb.NewTraceServiceClient(conn),
+		logs:    collogspb.NewLogsServiceClient(conn),
+		headers: metadata.New(opts.Headers),
+	}, nil
+}
+
+func (c *grpcClient) UploadSpans(ctx context.Context, spans []*tracepb.ResourceSpans) error {
+	ctx = metadata.NewOutgoingContext(ctx, c.headers)
+	_, err := c.trace.Export(ctx, &coltracepb.ExportTraceServiceRequest{ResourceSpans: spans})
+	return err
+}
+
+func (c *grpcClient) UploadLogs(ctx context.Context, logs []*logspb.ResourceLogs) error {
+	ctx = metadata.NewOutgoingContext(ctx, c.headers)
+	_, err := c.logs.Export(ctx, &collogspb.ExportLogsServiceRequest{ResourceLogs: logs})
+	return err
+}
+
+func (c *grpcClient) Close() error {
+	return c.conn.Close()
+}
+
+// httpClient is an otlpClient that speaks OTLP/HTTP with protobuf bodies.
+type httpClient struct {
+	endpoint string
+	headers  map[string]string
+	http     *http.Client
+}
+
+func newHTTPClient(opts Options) (*httpClient, error) {
+	tlsConfig, err := buildTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &httpClient{
+		endpoint: strings.TrimSuffix(opts.Endpoint, "/"),
+		headers:  opts.Headers,
+		http:     &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}},
+	}, nil
+}
+
+func (c *httpClient) UploadSpans(ctx context.Context, spans []*tracepb.ResourceSpans) error {
+	return c.post(ctx, "/v1/traces", &coltracepb.ExportTraceServiceRequest{ResourceSpans: spans})
+}
+
+func (c *httpClient) UploadLogs(ctx context.Context, logs []*logspb.ResourceLogs) error {
+	return c.post(ctx, "/v1/logs", &collogspb.ExportLogsServiceRequest{ResourceLogs: logs})
+}
+
+func (c *httpClient) Close() error {
+	c.http.CloseIdleConnections()
+	return nil
+}
+
+func (c *httpClient) post(ctx context.Context, path string, msg proto.Message) error {
+	body, err := proto.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal OTLP request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build OTLP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send OTLP request to %s: %w", c.endpoint, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body) //nolint:errcheck // drain to allow connection reuse
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("OTLP collector %s returned status %d", c.endpoint, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,278 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Hubble
+
+// Package otlpexporter implements a Hubble flow exporter sink that ships
+// flows to an OpenTelemetry collector over OTLP, alongside the existing
+// file-based exporter.
+package otlpexporter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+
+	flowpb "github.com/cilium/cilium/api/v1/flow"
+	v1 "github.com/cilium/cilium/pkg/hubble/api/v1"
+	"github.com/cilium/cilium/pkg/hubble/filters"
+)
+
+// Encoding selects how a Hubble flow is mapped onto an OTLP signal.
+type Encoding string
+
+const (
+	// EncodingFlow emits a dedicated flow log body per event.
+	EncodingFlow Encoding = "flow"
+	// EncodingLog emits the flow as a generic OTLP log record.
+	EncodingLog Encoding = "log"
+	// EncodingSpan emits the flow as an OTLP trace span, using the
+	// trace_id/span_id extracted from L7 events to correlate with
+	// existing traces.
+	EncodingSpan Encoding = "span"
+)
+
+// Options configures the OTLP exporter sink.
+type Options struct {
+	// Endpoint is the OTLP collector endpoint, e.g. "otel-collector:4317".
+	Endpoint string
+	// Protocol is either "grpc" or "http-protobuf".
+	Protocol string
+	// Headers are extra "key=value" headers sent with every OTLP request.
+	Headers map[string]string
+	// Insecure disables transport security for the collector connection.
+	Insecure bool
+	// TLSCertFile, TLSKeyFile and TLSCAFile configure the client TLS
+	// material used to connect to the collector.
+	TLSCertFile string
+	TLSKeyFile  string
+	TLSCAFile   string
+	// Encoding selects how flows are mapped onto OTLP signals.
+	Encoding Encoding
+	// BatchMaxSize is the maximum number of flows accumulated before a
+	// batch is flushed to the collector.
+	BatchMaxSize int
+	// BatchTimeout is the maximum time a partially filled batch is held
+	// before being flushed.
+	BatchTimeout time.Duration
+	// Fieldmask restricts the fields copied onto exported flows, mirroring
+	// the fieldmask already applied by the file exporter.
+	Fieldmask *fieldmaskpb.FieldMask
+	// Allowlist and Denylist mirror the filters used by the file exporter.
+	Allowlist []*flowpb.FlowFilter
+	Denylist  []*flowpb.FlowFilter
+}
+
+// otlpClient is the minimal surface the exporter needs from an OTLP
+// connection, implemented by the grpc and http-protobuf clients built by
+// newClient.
+type otlpClient interface {
+	UploadLogs(ctx context.Context, logs []*logspb.ResourceLogs) error
+	UploadSpans(ctx context.Context, spans []*tracepb.ResourceSpans) error
+	Close() error
+}
+
+// Exporter is a FlowLogExporter sink that batches Hubble flows and ships
+// them to an OTLP collector.
+type Exporter struct {
+	opts   Options
+	client otlpClient
+
+	allowFilters filters.FilterFuncs
+	denyFilters  filters.FilterFuncs
+
+	mu      sync.Mutex
+	pending []*flowpb.Flow
+	timer   *time.Timer
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewExporter creates a new OTLP flow exporter sink, dialing a client for
+// opts.Protocol and compiling opts.Allowlist/Denylist once up front.
+func NewExporter(opts Options) (*Exporter, error) {
+	if opts.Endpoint == "" {
+		return nil, fmt.Errorf("otlp exporter: endpoint must not be empty")
+	}
+	if opts.BatchMaxSize <= 0 {
+		return nil, fmt.Errorf("otlp exporter: batch max size must be positive, got %d", opts.BatchMaxSize)
+	}
+	if opts.BatchTimeout <= 0 {
+		return nil, fmt.Errorf("otlp exporter: batch timeout must be positive, got %s", opts.BatchTimeout)
+	}
+
+	client, err := newClient(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	allowFilters, err := filters.BuildFilterList(context.Background(), opts.Allowlist, filters.DefaultFilters)
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("otlp exporter: invalid hubble-otel-allowlist: %w", err)
+	}
+	denyFilters, err := filters.BuildFilterList(context.Background(), opts.Denylist, filters.DefaultFilters)
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("otlp exporter: invalid hubble-otel-denylist: %w", err)
+	}
+
+	e := &Exporter{
+		opts:         opts,
+		client:       client,
+		allowFilters: allowFilters,
+		denyFilters:  denyFilters,
+		pending:      make([]*flowpb.Flow, 0, opts.BatchMaxSize),
+		stopCh:       make(chan struct{}),
+	}
+	e.timer = time.AfterFunc(opts.BatchTimeout, e.flushOnTimeout)
+	return e, nil
+}
+
+// Export implements the FlowLogExporter interface shared with the file
+// exporter: it applies the allow/deny filters and fieldmask before queuing
+// the flow for batched delivery.
+func (e *Exporter) Export(ctx context.Context, event *v1.Event) error {
+	flow, ok := event.Event.(*flowpb.Flow)
+	if !ok {
+		return nil
+	}
+
+	if !e.matchesFilters(flow) {
+		return nil
+	}
+
+	if e.opts.Fieldmask != nil {
+		flow = applyFieldmask(flow, e.opts.Fieldmask)
+	}
+
+	e.mu.Lock()
+	e.pending = append(e.pending, flow)
+	full := len(e.pending) >= e.opts.BatchMaxSize
+	e.mu.Unlock()
+
+	if full {
+		return e.flush(ctx)
+	}
+	return nil
+}
+
+// Stop flushes any pending flows and closes the underlying OTLP client.
+func (e *Exporter) Stop() error {
+	var err error
+	e.stopOnce.Do(func() {
+		close(e.stopCh)
+		e.timer.Stop()
+		err = e.flush(context.Background())
+		if cerr := e.client.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	})
+	return err
+}
+
+// flushOnTimeout flushes the current batch on the BatchTimeout cadence. The
+// timer is always rescheduled, even when the flush failed, so that a single
+// transient collector error doesn't permanently stop time-based flushing
+// for the rest of the process's life.
+func (e *Exporter) flushOnTimeout() {
+	defer func() {
+		select {
+		case <-e.stopCh:
+		default:
+			e.timer.Reset(e.opts.BatchTimeout)
+		}
+	}()
+
+	if err := e.flush(context.Background()); err != nil {
+		// flush has already taken the batch off the queue, so a failed
+		// batch is dropped; nothing else to do with a background flush
+		// error here.
+		_ = err
+	}
+}
+
+func (e *Exporter) flush(ctx context.Context) error {
+	e.mu.Lock()
+	if len(e.pending) == 0 {
+		e.mu.Unlock()
+		return nil
+	}
+	batch := e.pending
+	e.pending = make([]*flowpb.Flow, 0, e.opts.BatchMaxSize)
+	e.mu.Unlock()
+
+	switch e.opts.Encoding {
+	case EncodingSpan:
+		var spans []*tracepb.ResourceSpans
+		var skipped int
+		for _, flow := range batch {
+			span, ok := buildSpan(flow)
+			if !ok {
+				skipped++
+				continue
+			}
+			spans = append(spans, span)
+		}
+		if len(spans) == 0 {
+			return nil
+		}
+		if err := e.client.UploadSpans(ctx, spans); err != nil {
+			return fmt.Errorf("otlp exporter: failed to upload %d span(s) (%d skipped without trace context): %w", len(spans), skipped, err)
+		}
+	default: // EncodingFlow, EncodingLog
+		logs := make([]*logspb.ResourceLogs, 0, len(batch))
+		for _, flow := range batch {
+			logs = append(logs, buildLogRecord(flow, e.opts.Encoding))
+		}
+		if err := e.client.UploadLogs(ctx, logs); err != nil {
+			return fmt.Errorf("otlp exporter: failed to upload %d flow(s): %w", len(logs), err)
+		}
+	}
+
+	return nil
+}
+
+// matchesFilters applies the same allow/denylist semantics as the file
+// exporter: a flow matching the denylist is dropped, and an empty allowlist
+// matches everything. The allow/deny filter lists are compiled once in
+// NewExporter, not on every call, since filter evaluation runs on the flow
+// export hot path.
+func (e *Exporter) matchesFilters(flow *flowpb.Flow) bool {
+	event := &v1.Event{Event: flow}
+
+	if len(e.denyFilters) > 0 && e.denyFilters.MatchOne(event) {
+		return false
+	}
+	if len(e.allowFilters) == 0 {
+		return true
+	}
+	return e.allowFilters.MatchOne(event)
+}
+
+// applyFieldmask returns a copy of flow retaining only the top-level fields
+// named in mask, mirroring the fieldmask already validated by
+// config.validate().
+func applyFieldmask(flow *flowpb.Flow, mask *fieldmaskpb.FieldMask) *flowpb.Flow {
+	keep := make(map[protoreflect.Name]struct{}, len(mask.GetPaths()))
+	for _, path := range mask.GetPaths() {
+		keep[protoreflect.Name(path)] = struct{}{}
+	}
+
+	out := proto.Clone(flow).(*flowpb.Flow)
+	msg := out.ProtoReflect()
+	msg.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if _, ok := keep[fd.Name()]; !ok {
+			msg.Clear(fd)
+		}
+		return true
+	})
+	return out
+}
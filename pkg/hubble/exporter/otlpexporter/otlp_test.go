@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Hubble
+
+package otlpexporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	flowpb "github.com/cilium/cilium/api/v1/flow"
+)
+
+const testBatchTimeout = time.Minute
+
+func TestApplyFieldmask(t *testing.T) {
+	flow := &flowpb.Flow{
+		Time:    timestamppb.Now(),
+		Verdict: flowpb.Verdict_FORWARDED,
+		Summary: "TCP",
+	}
+
+	mask, err := fieldmaskpb.New(&flowpb.Flow{}, "verdict")
+	require.NoError(t, err)
+
+	out := applyFieldmask(flow, mask)
+	require.Equal(t, flowpb.Verdict_FORWARDED, out.GetVerdict())
+	require.Empty(t, out.GetSummary())
+	require.Nil(t, out.GetTime())
+
+	// The original flow must be untouched.
+	require.Equal(t, "TCP", flow.GetSummary())
+}
+
+func TestExporterMatchesFilters(t *testing.T) {
+	tests := []struct {
+		name  string
+		allow []*flowpb.FlowFilter
+		deny  []*flowpb.FlowFilter
+		flow  *flowpb.Flow
+		want  bool
+	}{
+		{
+			name: "empty allow and deny matches everything",
+			flow: &flowpb.Flow{Verdict: flowpb.Verdict_FORWARDED},
+			want: true,
+		},
+		{
+			name: "denylist drops a matching flow",
+			deny: []*flowpb.FlowFilter{{Verdict: []flowpb.Verdict{flowpb.Verdict_DROPPED}}},
+			flow: &flowpb.Flow{Verdict: flowpb.Verdict_DROPPED},
+			want: false,
+		},
+		{
+			name:  "allowlist requires a match",
+			allow: []*flowpb.FlowFilter{{Verdict: []flowpb.Verdict{flowpb.Verdict_DROPPED}}},
+			flow:  &flowpb.Flow{Verdict: flowpb.Verdict_FORWARDED},
+			want:  false,
+		},
+		{
+			name:  "denylist takes precedence over a matching allowlist",
+			allow: []*flowpb.FlowFilter{{Verdict: []flowpb.Verdict{flowpb.Verdict_DROPPED}}},
+			deny:  []*flowpb.FlowFilter{{Verdict: []flowpb.Verdict{flowpb.Verdict_DROPPED}}},
+			flow:  &flowpb.Flow{Verdict: flowpb.Verdict_DROPPED},
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := newTestExporter(t, tt.allow, tt.deny)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, e.matchesFilters(tt.flow))
+		})
+	}
+}
+
+// newTestExporter builds an Exporter for testing matchesFilters. It uses a
+// real gRPC client for a placeholder endpoint; grpc.NewClient does not
+// connect until the first RPC, and matchesFilters queues nothing to send.
+func newTestExporter(t *testing.T, allow, deny []*flowpb.FlowFilter) (*Exporter, error) {
+	t.Helper()
+	e, err := NewExporter(Options{
+		Endpoint:     "test:4317",
+		Protocol:     "grpc",
+		Encoding:     EncodingFlow,
+		BatchMaxSize: 1,
+		BatchTimeout: testBatchTimeout,
+		Allowlist:    allow,
+		Denylist:     deny,
+	})
+	if err == nil {
+		t.Cleanup(func() { e.Stop() })
+	}
+	return e, err
+}
@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Hubble
+
+package reproducer
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// NewReproduceCommand returns the `hubble reproduce <file>` command: it
+// replays the raw monitor events in a reproducer capture file through
+// parser and reports any flow that the current parser decodes differently
+// than what was recorded, without needing access to the reporting user's
+// cluster.
+func NewReproduceCommand(parser Parser) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reproduce <file>",
+		Short: "Replay a Hubble reproducer capture file against the current parser",
+		Long: "Replay the raw monitor events recorded by hubble-repro-capture-path\n" +
+			"through the current flow parser, and report every flow whose replayed\n" +
+			"output no longer matches what was recorded at capture time.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open capture file: %w", err)
+			}
+			defer f.Close()
+
+			diffs, err := Replay(f, parser)
+			if err != nil {
+				return err
+			}
+
+			if len(diffs) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "no differences found")
+				return nil
+			}
+
+			for _, d := range diffs {
+				fmt.Fprintf(cmd.OutOrStdout(), "record %d differs:\n  recorded: %v\n  replayed: %v\n", d.Index, d.Recorded, d.Replayed)
+			}
+			return fmt.Errorf("%d record(s) differ between capture and replay", len(diffs))
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Hubble
+
+package reproducer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/proto"
+
+	flowpb "github.com/cilium/cilium/api/v1/flow"
+)
+
+// Parser reparses a raw monitor event into a flow, the same way the live
+// observer pipeline does. It is satisfied by the observer package's parser.
+type Parser interface {
+	Decode(rawEvent []byte) (*flowpb.Flow, error)
+}
+
+// Diff describes a Record whose recorded flow no longer matches what the
+// current parser produces from the same raw event, indicating a parser
+// regression or intentional behavior change.
+type Diff struct {
+	// Index is the 0-based position of the record in the capture file.
+	Index int
+	// Recorded is the flow that was recorded at capture time.
+	Recorded *flowpb.Flow
+	// Replayed is the flow the current parser produces from the same raw
+	// event.
+	Replayed *flowpb.Flow
+}
+
+// Replay re-parses every Record read from r using parser, and reports a
+// Diff for each one whose replayed flow differs from the recorded flow. If
+// a record was captured with payloadFields redacted, the same fields are
+// cleared from the replayed flow before comparing, so redaction alone never
+// produces a spurious diff. Records with no raw event (e.g. from a capture
+// file predating unconditional RawEvent capture) are skipped, since there
+// is nothing to re-parse.
+func Replay(r io.Reader, parser Parser) ([]Diff, error) {
+	var diffs []Diff
+	scanner := bufio.NewScanner(r)
+	// Capture records may embed full flow payloads; grow past bufio's
+	// default 64KiB token limit.
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for i := 0; scanner.Scan(); i++ {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return diffs, fmt.Errorf("reproducer: failed to parse record %d: %w", i, err)
+		}
+		if len(rec.RawEvent) == 0 {
+			continue
+		}
+
+		replayed, err := parser.Decode(rec.RawEvent)
+		if err != nil {
+			return diffs, fmt.Errorf("reproducer: failed to replay record %d: %w", i, err)
+		}
+		if rec.PayloadRedacted {
+			replayed = redactPayload(replayed)
+		}
+
+		if !proto.Equal(rec.Flow, replayed) {
+			diffs = append(diffs, Diff{
+				Index:    i,
+				Recorded: rec.Flow,
+				Replayed: replayed,
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return diffs, fmt.Errorf("reproducer: failed to read capture file: %w", err)
+	}
+	return diffs, nil
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Hubble
+
+package reproducer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	flowpb "github.com/cilium/cilium/api/v1/flow"
+)
+
+// fakeParser decodes a raw event by looking it up in a fixed map, so tests
+// can control exactly what the "current parser" produces for a given raw
+// event without depending on the real monitor parser.
+type fakeParser map[string]*flowpb.Flow
+
+func (p fakeParser) Decode(rawEvent []byte) (*flowpb.Flow, error) {
+	flow, ok := p[string(rawEvent)]
+	if !ok {
+		return nil, fmt.Errorf("no fake decoding registered for %q", rawEvent)
+	}
+	return flow, nil
+}
+
+func marshalRecord(t *testing.T, rec Record) string {
+	t.Helper()
+	b, err := json.Marshal(rec)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestReplayNoDiff(t *testing.T) {
+	parser := fakeParser{"raw-1": {Summary: "TCP"}}
+	input := marshalRecord(t, Record{RawEvent: []byte("raw-1"), Flow: &flowpb.Flow{Summary: "TCP"}})
+
+	diffs, err := Replay(strings.NewReader(input), parser)
+	require.NoError(t, err)
+	require.Empty(t, diffs)
+}
+
+func TestReplayReportsDiff(t *testing.T) {
+	parser := fakeParser{"raw-1": {Summary: "UDP"}}
+	input := marshalRecord(t, Record{RawEvent: []byte("raw-1"), Flow: &flowpb.Flow{Summary: "TCP"}})
+
+	diffs, err := Replay(strings.NewReader(input), parser)
+	require.NoError(t, err)
+	require.Len(t, diffs, 1)
+	require.Equal(t, 0, diffs[0].Index)
+	require.Equal(t, "TCP", diffs[0].Recorded.GetSummary())
+	require.Equal(t, "UDP", diffs[0].Replayed.GetSummary())
+}
+
+func TestReplaySkipsRecordsWithoutRawEvent(t *testing.T) {
+	parser := fakeParser{}
+	input := marshalRecord(t, Record{Flow: &flowpb.Flow{Summary: "TCP"}})
+
+	diffs, err := Replay(strings.NewReader(input), parser)
+	require.NoError(t, err)
+	require.Empty(t, diffs)
+}
+
+func TestReplaySkipsPayloadDiffWhenRedacted(t *testing.T) {
+	parser := fakeParser{"raw-1": {Summary: "TCP", L7: &flowpb.Layer7{Type: flowpb.L7FlowType_REQUEST}}}
+	input := marshalRecord(t, Record{
+		RawEvent:        []byte("raw-1"),
+		Flow:            &flowpb.Flow{Summary: "TCP"},
+		PayloadRedacted: true,
+	})
+
+	diffs, err := Replay(strings.NewReader(input), parser)
+	require.NoError(t, err)
+	require.Empty(t, diffs, "redacted L7 should not be compared against the replayed flow's L7")
+}
+
+func TestReplayStillReportsNonPayloadDiffWhenRedacted(t *testing.T) {
+	parser := fakeParser{"raw-1": {Summary: "UDP", L7: &flowpb.Layer7{Type: flowpb.L7FlowType_REQUEST}}}
+	input := marshalRecord(t, Record{
+		RawEvent:        []byte("raw-1"),
+		Flow:            &flowpb.Flow{Summary: "TCP"},
+		PayloadRedacted: true,
+	})
+
+	diffs, err := Replay(strings.NewReader(input), parser)
+	require.NoError(t, err)
+	require.Len(t, diffs, 1)
+}
+
+func TestReplayMultipleRecordsIndexing(t *testing.T) {
+	parser := fakeParser{
+		"raw-1": {Summary: "TCP"},
+		"raw-2": {Summary: "UDP"},
+	}
+	var buf bytes.Buffer
+	buf.WriteString(marshalRecord(t, Record{RawEvent: []byte("raw-1"), Flow: &flowpb.Flow{Summary: "TCP"}}))
+	buf.WriteString("\n")
+	buf.WriteString(marshalRecord(t, Record{RawEvent: []byte("raw-2"), Flow: &flowpb.Flow{Summary: "ICMP"}}))
+
+	diffs, err := Replay(&buf, parser)
+	require.NoError(t, err)
+	require.Len(t, diffs, 1)
+	require.Equal(t, 1, diffs[0].Index)
+}
@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Hubble
+
+// Package reproducer implements a debug capture mode for the Hubble
+// observer pipeline: it records the raw pre-parse monitor events, the
+// resulting parsed flow and any exporter filter decisions as NDJSON, so a
+// user's bug report can be replayed against the current parser without
+// needing access to their cluster.
+package reproducer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	flowpb "github.com/cilium/cilium/api/v1/flow"
+	v1 "github.com/cilium/cilium/pkg/hubble/api/v1"
+	"github.com/cilium/cilium/pkg/hubble/filters"
+)
+
+// payloadFields lists the Flow fields that can carry application payload
+// bytes (e.g. HTTP/Kafka/DNS message contents), as opposed to flow metadata.
+// These are the only fields redactPayload clears.
+var payloadFields = []protoreflect.Name{"l7"}
+
+// Record is a single NDJSON line written to the capture file: the raw
+// monitor event as observed off the perf ring buffer, the flow the current
+// parser produced from it, and whether the configured exporter filters
+// would have let it through.
+type Record struct {
+	// RawEvent is the pre-parse monitor event payload. It is always
+	// populated, since Replay needs it to re-derive a flow regardless of
+	// IncludePayload.
+	RawEvent []byte `json:"rawEvent"`
+	// Flow is the flowpb.Flow the parser produced from RawEvent, with
+	// payloadFields cleared when PayloadRedacted is true.
+	Flow *flowpb.Flow `json:"flow"`
+	// PayloadRedacted reports whether payloadFields were cleared from Flow
+	// because the capture was taken with IncludePayload false. Replay uses
+	// this to apply the same redaction to the replayed flow before diffing.
+	PayloadRedacted bool `json:"payloadRedacted,omitempty"`
+	// ExporterMatch reports whether the configured exporter allow/denylist
+	// filters matched this flow.
+	ExporterMatch bool `json:"exporterMatch"`
+}
+
+// redactPayload returns a clone of flow with payloadFields cleared.
+func redactPayload(flow *flowpb.Flow) *flowpb.Flow {
+	out := proto.Clone(flow).(*flowpb.Flow)
+	msg := out.ProtoReflect()
+	for _, name := range payloadFields {
+		if fd := msg.Descriptor().Fields().ByName(name); fd != nil {
+			msg.Clear(fd)
+		}
+	}
+	return out
+}
+
+// Capture writes Records to an NDJSON file, rotated through the same
+// lumberjack settings used by the file exporter's ExportFile* options.
+type Capture struct {
+	out       io.WriteCloser
+	filter    filters.FilterFuncs
+	maxEvents int
+
+	mu             sync.Mutex
+	written        int
+	includePayload bool
+}
+
+// Options configures a Capture.
+type Options struct {
+	// Path is the NDJSON file to write captured events to.
+	Path string
+	// MaxEvents caps the number of events retained; 0 means unlimited.
+	MaxEvents int
+	// Filter restricts which flows are captured.
+	Filter []*flowpb.FlowFilter
+	// IncludePayload includes payloadFields (e.g. L7 message contents) in
+	// the recorded flow when true. The raw monitor event is always
+	// captured either way, since Replay needs it to re-derive a flow.
+	IncludePayload bool
+	// MaxSizeMB, MaxBackups and Compress configure rotation of the capture
+	// file, mirroring the file exporter's ExportFile* options.
+	MaxSizeMB  int
+	MaxBackups int
+	Compress   bool
+}
+
+// NewCapture opens (or creates) the NDJSON capture file at opts.Path,
+// rotated through the same lumberjack settings used by the file exporter's
+// ExportFile* options.
+func NewCapture(opts Options) (*Capture, error) {
+	if opts.Path == "" {
+		return nil, fmt.Errorf("reproducer: capture path must not be empty")
+	}
+
+	filterFuncs, err := filters.BuildFilterList(context.Background(), opts.Filter, filters.DefaultFilters)
+	if err != nil {
+		return nil, fmt.Errorf("reproducer: invalid hubble-repro-capture-filter: %w", err)
+	}
+
+	return &Capture{
+		out: &lumberjack.Logger{
+			Filename:   opts.Path,
+			MaxSize:    opts.MaxSizeMB,
+			MaxBackups: opts.MaxBackups,
+			Compress:   opts.Compress,
+		},
+		filter:         filterFuncs,
+		maxEvents:      opts.MaxEvents,
+		includePayload: opts.IncludePayload,
+	}, nil
+}
+
+// Write appends a Record for flow, derived from rawEvent, if it is within
+// maxEvents and matches the configured filter.
+func (c *Capture) Write(rawEvent []byte, flow *flowpb.Flow, exporterMatch bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.maxEvents > 0 && c.written >= c.maxEvents {
+		return nil
+	}
+
+	event := &v1.Event{Event: flow}
+	if len(c.filter) > 0 && !c.filter.MatchOne(event) {
+		return nil
+	}
+
+	redacted := !c.includePayload
+	if redacted {
+		flow = redactPayload(flow)
+	}
+
+	line, err := json.Marshal(Record{
+		RawEvent:        rawEvent,
+		Flow:            flow,
+		PayloadRedacted: redacted,
+		ExporterMatch:   exporterMatch,
+	})
+	if err != nil {
+		return fmt.Errorf("reproducer: failed to marshal record: %w", err)
+	}
+	line = append(line, '\n')
+
+	if _, err := c.out.Write(line); err != nil {
+		return fmt.Errorf("reproducer: failed to write record: %w", err)
+	}
+	c.written++
+	return nil
+}
+
+// Close closes the underlying capture file.
+func (c *Capture) Close() error {
+	return c.out.Close()
+}
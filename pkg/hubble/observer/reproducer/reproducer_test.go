@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Hubble
+
+package reproducer
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	flowpb "github.com/cilium/cilium/api/v1/flow"
+)
+
+type nopCloserBuffer struct{ bytes.Buffer }
+
+func (*nopCloserBuffer) Close() error { return nil }
+
+func TestCaptureWriteAlwaysRecordsRawEvent(t *testing.T) {
+	var buf nopCloserBuffer
+	c := &Capture{out: &buf, includePayload: false}
+
+	flow := &flowpb.Flow{Summary: "TCP", L7: &flowpb.Layer7{Type: flowpb.L7FlowType_REQUEST}}
+	require.NoError(t, c.Write([]byte("raw-1"), flow, true))
+
+	var rec Record
+	require.NoError(t, json.Unmarshal(bytes.TrimRight(buf.Bytes(), "\n"), &rec))
+	require.Equal(t, []byte("raw-1"), rec.RawEvent)
+	require.True(t, rec.PayloadRedacted)
+	require.Nil(t, rec.Flow.GetL7(), "L7 should be redacted when IncludePayload is false")
+}
+
+func TestCaptureWriteIncludesPayloadWhenEnabled(t *testing.T) {
+	var buf nopCloserBuffer
+	c := &Capture{out: &buf, includePayload: true}
+
+	flow := &flowpb.Flow{Summary: "TCP", L7: &flowpb.Layer7{Type: flowpb.L7FlowType_REQUEST}}
+	require.NoError(t, c.Write([]byte("raw-1"), flow, true))
+
+	var rec Record
+	require.NoError(t, json.Unmarshal(bytes.TrimRight(buf.Bytes(), "\n"), &rec))
+	require.Equal(t, []byte("raw-1"), rec.RawEvent)
+	require.False(t, rec.PayloadRedacted)
+	require.NotNil(t, rec.Flow.GetL7())
+}